@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// cdxEntry is one parsed line of a .warc.os.cdx.gz index: enough to locate
+// a record's WARC-gzip member (Offset, Length) and verify it hasn't
+// changed since a previous run (Digest) without re-reading the record
+// itself.
+type cdxEntry struct {
+	ID        string
+	TargetURI string
+	Digest    string
+	Offset    int64
+	Length    int64
+}
+
+// parseCDXLine parses one line of the standard 11-field CDX format:
+//
+//	urlkey timestamp original mimetype statuscode digest redirect robotflags length offset filename
+func parseCDXLine(line string) (cdxEntry, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 10 {
+		return cdxEntry{}, fmt.Errorf("cdx: expected at least 10 fields, got %d: %q", len(fields), line)
+	}
+
+	targetURI := fields[2]
+	digest := fields[5]
+
+	length, err := strconv.ParseInt(fields[8], 10, 64)
+	if err != nil {
+		return cdxEntry{}, fmt.Errorf("cdx: bad length %q: %w", fields[8], err)
+	}
+
+	offset, err := strconv.ParseInt(fields[9], 10, 64)
+	if err != nil {
+		return cdxEntry{}, fmt.Errorf("cdx: bad offset %q: %w", fields[9], err)
+	}
+
+	pathParts := strings.Split(targetURI, "/")
+	id := pathParts[len(pathParts)-1]
+
+	return cdxEntry{
+		ID:        id,
+		TargetURI: targetURI,
+		Digest:    digest,
+		Offset:    offset,
+		Length:    length,
+	}, nil
+}
+
+// readCDX parses every record line in a .warc.os.cdx.gz index, skipping
+// the leading " CDX ..." header line.
+func readCDX(r io.Reader) ([]cdxEntry, error) {
+	scanner := bufio.NewScanner(r)
+
+	var entries []cdxEntry
+	first := true
+	for scanner.Scan() {
+		if first {
+			first = false
+			continue
+		}
+
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		entry, err := parseCDXLine(line)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
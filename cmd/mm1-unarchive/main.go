@@ -0,0 +1,249 @@
+// Command mm1-unarchive extracts Nintendo course-world level captures out
+// of WARC archives into thumbnail/course-data archives, one per level. It
+// is a thin CLI over the github.com/arfrie22/mm1-unarchive/pkg/mm1warc
+// library.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/k0kubun/go-ansi"
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/arfrie22/mm1-unarchive/pkg/mm1warc"
+)
+
+// extractOptions controls how a single .warc.gz/.warc.os.cdx.gz pair is
+// processed by extractFile.
+type extractOptions struct {
+	Jobs   int
+	Format mm1warc.OutputFormat
+	// Force re-extracts every record, ignoring output/.manifest entirely.
+	Force bool
+	// Only, if non-empty, is a glob (filepath.Match syntax) matched against a
+	// level's id; records that don't match are skipped.
+	Only string
+	// FromOffset, if non-zero, overrides manifest-driven resume and seeks
+	// the WARC reader directly to this byte offset.
+	FromOffset int64
+}
+
+func extractFile(archiveFile string, opts extractOptions) {
+	cdxFile, err := os.OpenFile(archiveFile+".warc.os.cdx.gz", os.O_RDONLY, 0644)
+	if err != nil {
+		panic(err)
+	}
+	defer cdxFile.Close()
+
+	cdxReader, _, err := mm1warc.DetectCompressionReader(cdxFile)
+	if err != nil {
+		panic(err)
+	}
+
+	entries, err := readCDX(cdxReader)
+	if err != nil {
+		panic(err)
+	}
+
+	manifest, err := openManifest("output")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	digestByID := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		digestByID[entry.ID] = entry.Digest
+	}
+
+	// Resume from the lowest offset of any not-yet-done record, since CDX
+	// entries are sorted by URL key rather than by file offset.
+	startOffset := opts.FromOffset
+	if startOffset == 0 && !opts.Force {
+		startOffset = resumeStartOffset(entries, manifest, opts.Format, "output")
+	}
+
+	file, err := os.OpenFile(archiveFile+".warc.gz", os.O_RDONLY, 0644)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	// ExtractWARC (via the vendored warc.Reader) only understands a
+	// gzip .warc.gz, one independent gzip member per record -- unlike
+	// the CDX index above, it can't transparently accept whatever
+	// DetectCompression recognizes. Check for that plainly here instead
+	// of letting a non-gzip file fail deep inside the vendored reader
+	// with an opaque error.
+	peek := make([]byte, 6)
+	n, err := io.ReadFull(file, peek)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		log.Fatal(err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		log.Fatal(err)
+	}
+	if compression := mm1warc.DetectCompression(peek[:n]); compression != mm1warc.CompressionGzip {
+		log.Fatalf("%s is %s-compressed, not gzip; only gzip .warc.gz files are supported", archiveFile+".warc.gz", compression)
+	}
+
+	if startOffset > 0 {
+		if _, err := file.Seek(startOffset, io.SeekStart); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	name := strings.Split(archiveFile, "/")
+	bar := progressbar.NewOptions(len(entries),
+		progressbar.OptionSetWriter(ansi.NewAnsiStdout()),
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionSetWidth(15),
+		progressbar.OptionSetDescription("[cyan]["+name[len(name)-1]+"][reset] Processing files"),
+		progressbar.OptionShowCount(),
+		progressbar.OptionShowIts(),
+		progressbar.OptionShowElapsedTimeOnFinish(),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "[green]=[reset]",
+			SaucerHead:    "[green]>[reset]",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}))
+
+	skippedBeforeSeek := 0
+	for _, entry := range entries {
+		if entry.Offset < startOffset {
+			skippedBeforeSeek++
+		}
+	}
+	bar.Add(skippedBeforeSeek)
+
+	var barMu sync.Mutex
+	extractor := mm1warc.NewExtractor(mm1warc.Options{
+		OutputDir: "output",
+		Format:    opts.Format,
+		Jobs:      opts.Jobs,
+		Filter: func(id string) bool {
+			if opts.Only != "" {
+				matched, err := filepath.Match(opts.Only, id)
+				if err != nil {
+					log.Fatal(err)
+				}
+				if !matched {
+					return false
+				}
+			}
+
+			if !opts.Force && manifest.isDone(id, digestByID[id], mm1warc.ArchivePath(opts.Format, "output", id)) {
+				return false
+			}
+
+			return true
+		},
+		Progress: func(id string, err error) {
+			barMu.Lock()
+			bar.Add(1)
+			barMu.Unlock()
+		},
+	})
+
+	results, err := extractor.ExtractWARC(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var failed []string
+	for result := range results {
+		if result.Err != nil {
+			log.Println("error converting", result.ID, ":", result.Err)
+			failed = append(failed, result.ID)
+			continue
+		}
+		if digest, ok := digestByID[result.ID]; ok {
+			if err := manifest.markDone(result.ID, digest); err != nil {
+				log.Println("recording manifest entry for", result.ID, ":", err)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		log.Printf("%d level(s) failed to convert: %s", len(failed), strings.Join(failed, ", "))
+	}
+}
+
+func extractDir(dir string, files []os.DirEntry, opts extractOptions) {
+	archiveFiles := []string{}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".warc.gz") {
+			archiveFiles = append(archiveFiles, dir+"/"+strings.TrimSuffix(file.Name(), ".warc.gz"))
+		}
+	}
+
+	for i, archiveFile := range archiveFiles {
+		log.Println("Processing", archiveFile, i+1, "/", len(archiveFiles))
+		extractFile(archiveFile, opts)
+		fmt.Println("\n Finished")
+	}
+}
+
+func main() {
+	formatFlag := flag.String("format", string(mm1warc.FormatTarZst), "output archive format: tar.zst, tar.gz, tar.xz, tar.bz2, zip, or dir")
+	jobsFlag := flag.Int("jobs", runtime.NumCPU(), "number of concurrent extraction workers")
+	forceFlag := flag.Bool("force", false, "re-extract every record, ignoring output/.manifest")
+	onlyFlag := flag.String("only", "", "only extract levels whose id matches this glob")
+	fromOffsetFlag := flag.Int64("from-offset", 0, "seek the WARC reader directly to this byte offset instead of resuming from the manifest")
+	flag.Parse()
+
+	format, err := mm1warc.ParseFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := extractOptions{
+		Jobs:       *jobsFlag,
+		Format:     format,
+		Force:      *forceFlag,
+		Only:       *onlyFlag,
+		FromOffset: *fromOffsetFlag,
+	}
+
+	os.MkdirAll("output/", 0755)
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatal("No file specified, please provide a .warc.gz or .warc.os.cdx.gz file")
+	}
+
+	var archiveFile string
+	if strings.HasSuffix(args[0], ".warc.gz") {
+		archiveFile = strings.TrimSuffix(args[0], ".warc.gz")
+		extractFile(archiveFile, opts)
+	} else if strings.HasSuffix(args[0], ".warc.os.cdx.gz") {
+		archiveFile = strings.TrimSuffix(args[0], ".warc.os.cdx.gz")
+		extractFile(archiveFile, opts)
+	} else if strings.HasSuffix(args[0], ".warc.") {
+		archiveFile = strings.TrimSuffix(args[0], ".warc.")
+		extractFile(archiveFile, opts)
+	} else {
+		fileInfo, err := os.Stat(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if fileInfo.IsDir() {
+			files, err := os.ReadDir(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+			extractDir(args[0], files, opts)
+		} else {
+			log.Fatal("Invalid file type, must be a .warc.gz or .warc.os.cdx.gz file, and both should be in the same directory")
+		}
+	}
+}
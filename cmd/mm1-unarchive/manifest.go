@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// manifestEntry records that a level was successfully extracted, along
+// with the CDX digest of the input record it came from, so a later run can
+// tell whether the underlying WARC record has changed since.
+type manifestEntry struct {
+	Digest string `json:"digest"`
+	Done   bool   `json:"done"`
+}
+
+// manifest is a JSON file under output/.manifest that tracks which levels
+// have already been extracted, so an interrupted extraction can resume
+// without redoing hours of work.
+type manifest struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]manifestEntry
+}
+
+// openManifest loads output/.manifest/manifest.json if it exists, or
+// starts empty if this is the first run.
+func openManifest(outputDir string) (*manifest, error) {
+	m := &manifest{
+		path:    filepath.Join(outputDir, ".manifest", "manifest.json"),
+		entries: map[string]manifestEntry{},
+	}
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &m.entries); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// isDone reports whether id was already extracted from an input record
+// with the given digest, and its output file still exists.
+func (m *manifest) isDone(id, digest, outputPath string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[id]
+	if !ok || !entry.Done || entry.Digest != digest {
+		return false
+	}
+
+	_, err := os.Stat(outputPath)
+	return err == nil
+}
+
+// markDone records id as successfully extracted from the record with the
+// given digest, persisting the manifest immediately so a crash right after
+// doesn't lose the progress just made.
+func (m *manifest) markDone(id, digest string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[id] = manifestEntry{Digest: digest, Done: true}
+	return m.saveLocked()
+}
+
+func (m *manifest) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, m.path)
+}
@@ -0,0 +1,29 @@
+package main
+
+import "github.com/arfrie22/mm1-unarchive/pkg/mm1warc"
+
+// resumeStartOffset returns the byte offset extractFile should seek the
+// WARC reader to before resuming: the minimum offset across every entry
+// that isn't already done, since CDX entries are sorted by URL key rather
+// than by file offset and the WARC reader only ever moves forward. It
+// returns 0 (start from the beginning) if every entry is done.
+func resumeStartOffset(entries []cdxEntry, manifest *manifest, format mm1warc.OutputFormat, outputDir string) int64 {
+	var (
+		startOffset int64
+		haveStart   bool
+	)
+
+	for _, entry := range entries {
+		outputPath := mm1warc.ArchivePath(format, outputDir, entry.ID)
+		if manifest.isDone(entry.ID, entry.Digest, outputPath) {
+			continue
+		}
+
+		if !haveStart || entry.Offset < startOffset {
+			startOffset = entry.Offset
+			haveStart = true
+		}
+	}
+
+	return startOffset
+}
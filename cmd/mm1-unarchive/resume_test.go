@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arfrie22/mm1-unarchive/pkg/mm1warc"
+)
+
+// markOutputPresent creates the on-disk marker isDone checks for, so a
+// manifest entry with Done: true is actually treated as done.
+func markOutputPresent(t *testing.T, outputDir string, format mm1warc.OutputFormat, id string) {
+	t.Helper()
+	path := mm1warc.ArchivePath(format, outputDir, id)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestResumeStartOffsetPicksMinimumNotMax checks that resumeStartOffset
+// scans every not-done entry rather than stopping at the first one it
+// sees. CDX entries are ordered by URL key, not by file offset, so a
+// version of this that takes the first not-done entry's offset can pick
+// one that sits after other not-yet-extracted records -- and since the
+// WARC reader only seeks forward, those earlier records would be skipped
+// permanently on resume.
+func TestResumeStartOffsetPicksMinimumNotMax(t *testing.T) {
+	outputDir := t.TempDir()
+	format := mm1warc.FormatDir
+
+	entries := []cdxEntry{
+		{ID: "level-0003", Digest: "d3", Offset: 3000},
+		{ID: "level-0002", Digest: "d2", Offset: 2000},
+		{ID: "level-0001", Digest: "d1", Offset: 1000},
+	}
+
+	markOutputPresent(t, outputDir, format, "level-0003")
+	m := &manifest{entries: map[string]manifestEntry{
+		"level-0003": {Digest: "d3", Done: true},
+	}}
+
+	got := resumeStartOffset(entries, m, format, outputDir)
+	if got != 1000 {
+		t.Fatalf("resumeStartOffset() = %d, want 1000 (the lowest not-done offset)", got)
+	}
+}
+
+// TestResumeStartOffsetAllDone checks the zero-value fallback when every
+// entry is already done.
+func TestResumeStartOffsetAllDone(t *testing.T) {
+	outputDir := t.TempDir()
+	format := mm1warc.FormatDir
+
+	entries := []cdxEntry{
+		{ID: "level-0001", Digest: "d1", Offset: 1000},
+		{ID: "level-0002", Digest: "d2", Offset: 2000},
+	}
+
+	markOutputPresent(t, outputDir, format, "level-0001")
+	markOutputPresent(t, outputDir, format, "level-0002")
+	m := &manifest{entries: map[string]manifestEntry{
+		"level-0001": {Digest: "d1", Done: true},
+		"level-0002": {Digest: "d2", Done: true},
+	}}
+
+	got := resumeStartOffset(entries, m, format, outputDir)
+	if got != 0 {
+		t.Fatalf("resumeStartOffset() = %d, want 0 when every entry is done", got)
+	}
+}
@@ -0,0 +1,210 @@
+package mm1warc
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// ArchiveWriter packages a fixed set of named files produced from a single
+// level into whatever on-disk representation the chosen OutputFormat uses.
+// Implementations are not safe for concurrent use by multiple goroutines on
+// the same instance, but separate instances (one per level) are.
+type ArchiveWriter interface {
+	// WriteFile adds a file with the given name and contents to the archive.
+	WriteFile(name string, data []byte) error
+	// Close flushes and closes the archive, and any underlying file it owns.
+	Close() error
+}
+
+// NewArchiveWriter opens an archive (or, for FormatDir, a directory) at
+// outputDir/id.<ext> and returns an ArchiveWriter ready to receive files.
+func NewArchiveWriter(format OutputFormat, outputDir, id string) (ArchiveWriter, error) {
+	path := ArchivePath(format, outputDir, id)
+	switch format {
+	case FormatTarZst:
+		return newTarArchiveWriter(path, wrapZstd)
+	case FormatTarGz:
+		return newTarArchiveWriter(path, wrapGzip)
+	case FormatTarXz:
+		return newTarArchiveWriter(path, wrapXz)
+	case FormatTarBz2:
+		return newTarArchiveWriter(path, wrapBzip2)
+	case FormatZip:
+		return newZipArchiveWriter(path)
+	case FormatDir:
+		return newDirArchiveWriter(path)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// compressionWrapper wraps a raw file handle with the compressor a tar
+// format needs; it returns the writer to feed into archive/tar along with a
+// closer that flushes the compressor (but not the underlying file, which
+// the caller closes separately).
+type compressionWrapper func(w io.Writer) (io.Writer, io.Closer, error)
+
+func wrapZstd(w io.Writer) (io.Writer, io.Closer, error) {
+	zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return nil, nil, err
+	}
+	return zw, zw, nil
+}
+
+func wrapGzip(w io.Writer) (io.Writer, io.Closer, error) {
+	gw := gzip.NewWriter(w)
+	return gw, gw, nil
+}
+
+func wrapXz(w io.Writer) (io.Writer, io.Closer, error) {
+	xw, err := xz.NewWriter(w)
+	if err != nil {
+		return nil, nil, err
+	}
+	return xw, xw, nil
+}
+
+func wrapBzip2(w io.Writer) (io.Writer, io.Closer, error) {
+	bw, err := bzip2.NewWriter(w, &bzip2.WriterConfig{Level: bzip2.BestCompression})
+	if err != nil {
+		return nil, nil, err
+	}
+	return bw, bw, nil
+}
+
+// newTarZstArchiveWriterWithEncoder opens a tar.zst archive reusing an
+// existing *zstd.Encoder instead of constructing a new one, since
+// zstd.NewWriter is comparatively expensive. The encoder is Reset onto the
+// new file and Closed (flushing the finished frame, not tearing the
+// encoder down) when the returned writer is closed.
+func newTarZstArchiveWriterWithEncoder(outputDir, id string, enc *zstd.Encoder) (*tarArchiveWriter, error) {
+	file, err := os.Create(ArchivePath(FormatTarZst, outputDir, id))
+	if err != nil {
+		return nil, err
+	}
+
+	enc.Reset(file)
+
+	return &tarArchiveWriter{
+		file:       file,
+		compressor: enc,
+		tarWriter:  tar.NewWriter(enc),
+	}, nil
+}
+
+// tarArchiveWriter implements ArchiveWriter for the tar.{zst,gz,xz,bz2}
+// formats: a tar stream piped through a single compressor into one file.
+type tarArchiveWriter struct {
+	file       *os.File
+	compressor io.Closer
+	tarWriter  *tar.Writer
+}
+
+func newTarArchiveWriter(path string, wrap compressionWrapper) (*tarArchiveWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, closer, err := wrap(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &tarArchiveWriter{
+		file:       file,
+		compressor: closer,
+		tarWriter:  tar.NewWriter(compressed),
+	}, nil
+}
+
+func (w *tarArchiveWriter) WriteFile(name string, data []byte) error {
+	if err := w.tarWriter.WriteHeader(&tar.Header{
+		Name:    name,
+		Size:    int64(len(data)),
+		Mode:    0644,
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := w.tarWriter.Write(data)
+	return err
+}
+
+func (w *tarArchiveWriter) Close() error {
+	if err := w.tarWriter.Close(); err != nil {
+		w.compressor.Close()
+		w.file.Close()
+		return err
+	}
+	if err := w.compressor.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// zipArchiveWriter implements ArchiveWriter for FormatZip.
+type zipArchiveWriter struct {
+	file      *os.File
+	zipWriter *zip.Writer
+}
+
+func newZipArchiveWriter(path string) (*zipArchiveWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &zipArchiveWriter{file: file, zipWriter: zip.NewWriter(file)}, nil
+}
+
+func (w *zipArchiveWriter) WriteFile(name string, data []byte) error {
+	fw, err := w.zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+func (w *zipArchiveWriter) Close() error {
+	if err := w.zipWriter.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// dirArchiveWriter implements ArchiveWriter by writing each file directly
+// into its own directory, for callers who want extracted levels available
+// as plain files instead of an archive.
+type dirArchiveWriter struct {
+	dir string
+}
+
+func newDirArchiveWriter(dir string) (*dirArchiveWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &dirArchiveWriter{dir: dir}, nil
+}
+
+func (w *dirArchiveWriter) WriteFile(name string, data []byte) error {
+	return os.WriteFile(filepath.Join(w.dir, name), data, 0644)
+}
+
+func (w *dirArchiveWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,143 @@
+package mm1warc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ash0HeaderSize is the fixed 16-byte header that precedes every ASH0
+// bundle. There is no field anywhere in it for the compressed payload
+// length: the vendored decompressor (github.com/PretendoNetwork/ASH0)
+// only knows a bundle is exhausted once it has produced its declared
+// number of *decompressed* bytes via bit-level Huffman decoding, reading
+// compressed words on demand as it goes. So, unlike the decompressed
+// size, a bundle's compressed extent can't be computed up front from the
+// header alone.
+const ash0HeaderSize = 16
+
+// ash0MagicPrefix is the 3 bytes every ASH0 header starts with. The 4th
+// header byte is a format flag (typically 0x30, the ASCII '0' the format
+// is named for) that the vendored decompressor's own magic check --
+// IsAshCompressed -- masks out before comparing, so it's treated as a
+// flag here too rather than part of the magic.
+var ash0MagicPrefix = []byte("ASH")
+
+// maxAsh0DecompressedSize sanity-bounds a candidate header's declared
+// decompressed size. Real course-world thumbnails and CDT worlds are at
+// most a few hundred KiB; a "header" claiming more almost certainly isn't
+// one. Since the format has no compressed-length field to validate a
+// candidate header against, this is what lets the splitter tell a
+// genuine bundle boundary apart from the literal bytes "ASH" turning up
+// inside a payload. It must stay below 1<<24: the size field is only 24
+// bits wide, so a looser bound would never reject anything.
+const maxAsh0DecompressedSize = 8 << 20 // 8 MiB
+
+var (
+	// ErrTruncated is returned when the stream ends with the magic
+	// prefix present but too few bytes left for a full header.
+	ErrTruncated = errors.New("mm1warc: truncated ASH0 stream")
+	// ErrBadMagic is returned when no plausible ASH0 header is found
+	// anywhere in the data.
+	ErrBadMagic = errors.New("mm1warc: bad ASH0 magic")
+)
+
+// ash0HeaderValid reports whether data begins with a plausible ASH0
+// header: the magic prefix, a full header's worth of bytes, and a sanely
+// bounded decompressed size.
+func ash0HeaderValid(data []byte) bool {
+	if len(data) < ash0HeaderSize || !bytes.HasPrefix(data, ash0MagicPrefix) {
+		return false
+	}
+	size := binary.BigEndian.Uint32(data[4:8]) & 0x00FFFFFF
+	return size > 0 && size <= maxAsh0DecompressedSize
+}
+
+// Ash0Splitter locates each ASH0 bundle in a stream of concatenated
+// bundles by its header. Because the format has no declared compressed
+// length, a returned bundle is the rest of the stream from its header
+// onward, not a slice ending where the next bundle begins: ash0.Decompress
+// reads only as many bytes as its declared decompressed size requires and
+// safely ignores everything after, including later bundles' bytes.
+type Ash0Splitter struct {
+	data []byte
+	pos  int
+}
+
+// NewAsh0Splitter reads all of r and wraps it for splitting. The stream
+// is buffered up front rather than consumed incrementally, since a
+// bundle's valid extent can only be expressed as "to the end of the
+// buffer" and not a length known ahead of time.
+func NewAsh0Splitter(r io.Reader) (*Ash0Splitter, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Ash0Splitter{data: data}, nil
+}
+
+// Next returns the next bundle in the stream: data from the next
+// plausible ASH0 header to the end of the buffer. It returns io.EOF once
+// no further header can be found, or ErrTruncated if the magic prefix is
+// present but not enough bytes remain to form a full header.
+func (s *Ash0Splitter) Next() ([]byte, error) {
+	for s.pos < len(s.data) {
+		idx := bytes.Index(s.data[s.pos:], ash0MagicPrefix)
+		if idx == -1 {
+			s.pos = len(s.data)
+			return nil, io.EOF
+		}
+
+		start := s.pos + idx
+		candidate := s.data[start:]
+		if len(candidate) < ash0HeaderSize {
+			s.pos = len(s.data)
+			return nil, ErrTruncated
+		}
+
+		if ash0HeaderValid(candidate) {
+			s.pos = start + ash0HeaderSize
+			return candidate, nil
+		}
+
+		// Not a real header, just the bytes "ASH" showing up inside a
+		// payload -- keep looking past it.
+		s.pos = start + 1
+	}
+
+	return nil, io.EOF
+}
+
+// All reads every bundle in the stream. It returns ErrBadMagic if the
+// stream contained no plausible bundle at all.
+func (s *Ash0Splitter) All() ([][]byte, error) {
+	var bundles [][]byte
+	for {
+		bundle, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return bundles, err
+		}
+		bundles = append(bundles, bundle)
+	}
+
+	if len(bundles) == 0 {
+		return nil, ErrBadMagic
+	}
+
+	return bundles, nil
+}
+
+// splitAsh0Bundle splits data into separate ASH0 bundles, one per header
+// found, ignoring any leading bytes (e.g. HTTP response headers) before
+// the first one.
+func splitAsh0Bundle(data []byte) ([][]byte, error) {
+	splitter, err := NewAsh0Splitter(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return splitter.All()
+}
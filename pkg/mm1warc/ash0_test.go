@@ -0,0 +1,162 @@
+package mm1warc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// makeAsh0Bundle builds a real-shaped ASH0 header (big-endian, 3-byte
+// magic + 1-byte flag, a 24-bit decompressed size at offset 4, and a
+// cursor-2 byte offset at offset 8, as read by the vendored decompressor)
+// followed by payload.
+func makeAsh0Bundle(decompressedSize uint32, payload []byte) []byte {
+	header := make([]byte, ash0HeaderSize)
+	copy(header[:3], ash0MagicPrefix)
+	header[3] = 0x30 // flag byte, masked out by real header checks
+	binary.BigEndian.PutUint32(header[4:8], decompressedSize&0x00FFFFFF)
+	binary.BigEndian.PutUint32(header[8:12], ash0HeaderSize)
+	return append(header, payload...)
+}
+
+func FuzzAsh0Splitter(f *testing.F) {
+	f.Add([]byte{})
+	f.Add(ash0MagicPrefix)
+	f.Add(makeAsh0Bundle(500, nil))
+	f.Add(makeAsh0Bundle(500, []byte("hello world")))
+	f.Add(append(makeAsh0Bundle(500, []byte("ASH payload")), makeAsh0Bundle(600, []byte("second bundle"))...))
+	f.Add(makeAsh0Bundle(500, nil)[:ash0HeaderSize-1])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		splitter, err := NewAsh0Splitter(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("NewAsh0Splitter: %v", err)
+		}
+
+		bundles, err := splitter.All()
+		if err != nil {
+			if !errors.Is(err, ErrTruncated) && !errors.Is(err, ErrBadMagic) {
+				t.Fatalf("unexpected error type: %v", err)
+			}
+			return
+		}
+
+		for _, bundle := range bundles {
+			if len(bundle) < ash0HeaderSize {
+				t.Fatalf("bundle shorter than header: %d bytes", len(bundle))
+			}
+			if !bytes.Equal(bundle[:3], ash0MagicPrefix) {
+				t.Fatalf("bundle missing ASH0 magic: %x", bundle[:3])
+			}
+		}
+	})
+}
+
+func TestAsh0SplitterPayloadContainingMagic(t *testing.T) {
+	// The payload contains the literal bytes "ASH" immediately followed
+	// by a size field well past maxAsh0DecompressedSize, so it must not
+	// be mistaken for a second bundle's header.
+	badCandidate := append([]byte("ASH"), 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+	first := makeAsh0Bundle(500, append([]byte("contains "), badCandidate...))
+	second := makeAsh0Bundle(600, []byte("second"))
+	data := append(append([]byte{}, first...), second...)
+
+	splitter, err := NewAsh0Splitter(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewAsh0Splitter: %v", err)
+	}
+
+	bundles, err := splitter.All()
+	if err != nil {
+		t.Fatalf("All() returned error: %v", err)
+	}
+	if len(bundles) != 2 {
+		t.Fatalf("expected 2 bundles, got %d", len(bundles))
+	}
+	// The first bundle is returned as the full suffix from its header
+	// onward, which includes the second bundle's bytes too.
+	if !bytes.Equal(bundles[0], data) {
+		t.Fatalf("first bundle mismatch")
+	}
+	if !bytes.Equal(bundles[1], second) {
+		t.Fatalf("second bundle mismatch")
+	}
+}
+
+func TestAsh0SplitterTruncatedHeader(t *testing.T) {
+	data := makeAsh0Bundle(500, nil)[:ash0HeaderSize-1]
+	splitter, err := NewAsh0Splitter(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewAsh0Splitter: %v", err)
+	}
+
+	_, err = splitter.All()
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected ErrTruncated, got %v", err)
+	}
+}
+
+func TestAsh0SplitterBadMagic(t *testing.T) {
+	data := []byte("no ash header anywhere in this data")
+	splitter, err := NewAsh0Splitter(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewAsh0Splitter: %v", err)
+	}
+
+	_, err = splitter.All()
+	if !errors.Is(err, ErrBadMagic) {
+		t.Fatalf("expected ErrBadMagic, got %v", err)
+	}
+}
+
+func TestAsh0SplitterImplausibleSizeIsNotAHeader(t *testing.T) {
+	header := make([]byte, ash0HeaderSize)
+	copy(header[:3], ash0MagicPrefix)
+	binary.BigEndian.PutUint32(header[4:8], maxAsh0DecompressedSize+1)
+	data := append(header, []byte("trailing")...)
+
+	splitter, err := NewAsh0Splitter(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewAsh0Splitter: %v", err)
+	}
+
+	_, err = splitter.All()
+	if !errors.Is(err, ErrBadMagic) {
+		t.Fatalf("expected ErrBadMagic for an implausible decompressed size, got %v", err)
+	}
+}
+
+func TestAsh0SplitterMultipleBundles(t *testing.T) {
+	bundles := [][]byte{
+		makeAsh0Bundle(100, []byte("thumbnail0")),
+		makeAsh0Bundle(200, []byte("course data")),
+		makeAsh0Bundle(300, []byte("course data sub")),
+		makeAsh0Bundle(400, []byte("thumbnail1")),
+	}
+
+	var data []byte
+	for _, b := range bundles {
+		data = append(data, b...)
+	}
+
+	splitter, err := NewAsh0Splitter(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewAsh0Splitter: %v", err)
+	}
+
+	got, err := splitter.All()
+	if err != nil {
+		t.Fatalf("All() returned error: %v", err)
+	}
+	if len(got) != len(bundles) {
+		t.Fatalf("expected %d bundles, got %d", len(bundles), len(got))
+	}
+	for i, want := range bundles {
+		// Each returned bundle is its header onward to the end of the
+		// whole buffer, not sliced at the next bundle's start.
+		if !bytes.HasPrefix(got[i], want) {
+			t.Fatalf("bundle %d: got %x, want prefix %x", i, got[i], want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package mm1warc
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression identifies a byte-stream compression scheme detected from
+// magic bytes at the start of a file.
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionBzip2
+	CompressionXz
+	CompressionZstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionGzip:
+		return "gzip"
+	case CompressionBzip2:
+		return "bzip2"
+	case CompressionXz:
+		return "xz"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return "none"
+	}
+}
+
+// magicTable lists the magic-byte prefixes used to identify a compressed
+// stream without relying on file extensions.
+var magicTable = []struct {
+	magic []byte
+	kind  Compression
+}{
+	{[]byte{0x1F, 0x8B, 0x08}, CompressionGzip},
+	{[]byte{0x42, 0x5A, 0x68}, CompressionBzip2},
+	{[]byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00}, CompressionXz},
+	{[]byte{0x28, 0xB5, 0x2F, 0xFD}, CompressionZstd},
+}
+
+// DetectCompression sniffs the first few bytes of peek to determine which
+// compression scheme, if any, a stream was written with. It only inspects
+// bytes the caller has already read; callers should peek through a
+// bufio.Reader and pass its buffered bytes, or use DetectCompressionReader
+// below.
+func DetectCompression(peek []byte) Compression {
+	for _, entry := range magicTable {
+		if len(peek) >= len(entry.magic) && bytesEqual(peek[:len(entry.magic)], entry.magic) {
+			return entry.kind
+		}
+	}
+	return CompressionNone
+}
+
+func bytesEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectCompressionReader peeks at the head of r and returns an io.Reader
+// that transparently decompresses whatever scheme it finds, along with the
+// detected Compression.
+//
+// This only applies to streams read start-to-finish as one blob, like the
+// .warc.os.cdx.gz index: it's what readCDX's caller uses instead of
+// assuming gzip.NewReader unconditionally. It is deliberately not used for
+// the main .warc.gz data file. The vendored warc.Reader (see
+// Extractor.ExtractWARC) always gzip-decodes internally and resets onto
+// the raw stream after each record to find the next one -- a format that
+// relies on one independent gzip member per record, which only a real
+// gzip .warc.gz has. A non-gzip archive isn't shaped that way (and
+// reshaping it would mean re-deriving WARC record boundaries ourselves,
+// duplicating what the vendored reader already does), and CDX offsets
+// used to seek into it for resumable extraction address gzip members
+// specifically, so they wouldn't carry over to a different compression
+// scheme either. A non-gzip .warc.gz is therefore still rejected, now
+// with a clear error instead of an opaque one from inside the vendored
+// reader -- see the compression check in cmd/mm1-unarchive's extractFile.
+func DetectCompressionReader(r io.Reader) (io.Reader, Compression, error) {
+	br := bufio.NewReader(r)
+
+	peek, err := br.Peek(6)
+	if err != nil && err != io.EOF {
+		return nil, CompressionNone, err
+	}
+
+	switch DetectCompression(peek) {
+	case CompressionGzip:
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, CompressionNone, fmt.Errorf("opening gzip stream: %w", err)
+		}
+		return gz, CompressionGzip, nil
+	case CompressionBzip2:
+		return bzip2.NewReader(br), CompressionBzip2, nil
+	case CompressionXz:
+		xr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, CompressionNone, fmt.Errorf("opening xz stream: %w", err)
+		}
+		return xr, CompressionXz, nil
+	case CompressionZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, CompressionNone, fmt.Errorf("opening zstd stream: %w", err)
+		}
+		return zr.IOReadCloser(), CompressionZstd, nil
+	default:
+		return br, CompressionNone, nil
+	}
+}
@@ -0,0 +1,28 @@
+package mm1warc
+
+import "github.com/klauspost/compress/zstd"
+
+// levelConverter opens per-level archive writers for one worker, using a
+// zstd encoder that is allocated once and Reset between files, since
+// zstd.NewWriter is comparatively expensive to construct per call.
+type levelConverter struct {
+	zstdEncoder *zstd.Encoder
+}
+
+func newLevelConverter() (*levelConverter, error) {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return nil, err
+	}
+	return &levelConverter{zstdEncoder: enc}, nil
+}
+
+// archiveWriter opens an ArchiveWriter for id under outputDir in format,
+// routing tar.zst output through this converter's reusable encoder and
+// everything else through the regular NewArchiveWriter path.
+func (c *levelConverter) archiveWriter(outputDir string, format OutputFormat, id string) (ArchiveWriter, error) {
+	if format == FormatTarZst {
+		return newTarZstArchiveWriterWithEncoder(outputDir, id, c.zstdEncoder)
+	}
+	return NewArchiveWriter(format, outputDir, id)
+}
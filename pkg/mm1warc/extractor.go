@@ -0,0 +1,208 @@
+package mm1warc
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/CorentinB/warc"
+)
+
+// Options configures an Extractor.
+type Options struct {
+	// OutputDir, if set, causes every decoded level to also be packaged
+	// into Format and written under OutputDir. Leave empty to decode
+	// in-memory only -- e.g. for a level browser consuming
+	// LevelResult.Files directly -- without touching disk.
+	OutputDir string
+	// Format selects the on-disk archive format when OutputDir is set.
+	// Defaults to FormatTarZst.
+	Format OutputFormat
+	// Jobs is the number of concurrent decode workers. Defaults to
+	// runtime.NumCPU() if <= 0.
+	Jobs int
+	// Logger receives diagnostic output about malformed WARC records.
+	// Defaults to log.Default().
+	Logger *log.Logger
+	// Progress, if non-nil, is called once per WARC response record
+	// after it has been handled (decoded and, if filtered out, skipped),
+	// with that record's id and any error. Records whose target URI cannot
+	// be parsed or whose content cannot be read are logged and not
+	// reported. It must be safe to call from multiple goroutines.
+	Progress func(id string, err error)
+	// Filter, if non-nil, is called with each record's id before it is
+	// decoded; returning false skips the record entirely. Callers use
+	// this to implement resumable extraction (skip ids already written)
+	// without paying for a decode that will be thrown away.
+	Filter func(id string) bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.Jobs <= 0 {
+		o.Jobs = runtime.NumCPU()
+	}
+	if o.Format == "" {
+		o.Format = FormatTarZst
+	}
+	if o.Logger == nil {
+		o.Logger = log.Default()
+	}
+	return o
+}
+
+// Extractor decodes WARC records of Nintendo course-world level captures
+// into Levels, optionally packaging and writing each one to disk.
+type Extractor struct {
+	opts Options
+}
+
+// NewExtractor returns an Extractor configured by opts, filling in
+// defaults for any zero-valued fields.
+func NewExtractor(opts Options) *Extractor {
+	return &Extractor{opts: opts.withDefaults()}
+}
+
+// LevelResult is one decoded (and, if Options.OutputDir is set, written)
+// level.
+type LevelResult struct {
+	ID    string
+	Files []LevelFile
+	Err   error
+}
+
+// extractJob is a single WARC record queued for decoding.
+type extractJob struct {
+	id   string
+	data []byte
+}
+
+// ExtractWARC streams every "response" record out of r, decoding each into
+// a Level across Options.Jobs worker goroutines, and returns a channel of
+// LevelResults in completion order (not record order). The channel is
+// closed once r is fully consumed and every worker has finished. Records
+// whose HTTP status was not 200, or that Options.Filter rejects, are not
+// sent on the channel at all.
+//
+// r must be gzip-compressed, one independent gzip member per record: the
+// vendored warc.Reader gzip-decodes internally and resets onto the raw
+// stream after each record to find the next member, so it can't accept
+// an arbitrarily-compressed r the way DetectCompressionReader's callers
+// elsewhere in this package can. See DetectCompressionReader's doc comment for
+// where that does and doesn't reach.
+func (e *Extractor) ExtractWARC(r io.Reader) (<-chan LevelResult, error) {
+	reader, err := warc.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make(chan extractJob, e.opts.Jobs*2)
+	results := make(chan LevelResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < e.opts.Jobs; i++ {
+		converter, err := newLevelConverter()
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer converter.zstdEncoder.Close()
+
+			for job := range jobs {
+				result, skip := e.process(converter, job)
+				if e.opts.Progress != nil {
+					e.opts.Progress(job.id, result.Err)
+				}
+				if skip {
+					continue
+				}
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		defer reader.Close()
+		defer close(jobs)
+
+		for {
+			record, err := reader.ReadRecord()
+			if err != nil {
+				break
+			}
+
+			if record.Header.Get("WARC-Type") != "response" {
+				continue
+			}
+
+			link, err := url.Parse(record.Header.Get("WARC-Target-URI"))
+			if err != nil {
+				e.opts.Logger.Println("parsing WARC-Target-URI:", err)
+				continue
+			}
+
+			pathParts := strings.Split(link.Path, "/")
+			id := pathParts[len(pathParts)-1]
+
+			if e.opts.Filter != nil && !e.opts.Filter(id) {
+				if e.opts.Progress != nil {
+					e.opts.Progress(id, nil)
+				}
+				continue
+			}
+
+			data, err := io.ReadAll(record.Content)
+			if err != nil {
+				e.opts.Logger.Println("reading record content for", id, ":", err)
+				continue
+			}
+
+			jobs <- extractJob{id: id, data: data}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results, nil
+}
+
+// process decodes job and, if Options.OutputDir is set, writes it to disk.
+// skip reports a non-200 record that should neither be sent on the results
+// channel nor counted as an error.
+func (e *Extractor) process(converter *levelConverter, job extractJob) (result LevelResult, skip bool) {
+	level, err := DecodeLevel(job.data)
+	if err != nil {
+		if err == ErrNotFound {
+			return LevelResult{ID: job.id}, true
+		}
+		return LevelResult{ID: job.id, Err: fmt.Errorf("decoding %s: %w", job.id, err)}, false
+	}
+
+	files := level.Files()
+
+	if e.opts.OutputDir != "" {
+		archive, err := converter.archiveWriter(e.opts.OutputDir, e.opts.Format, job.id)
+		if err != nil {
+			return LevelResult{ID: job.id, Err: err}, false
+		}
+		defer archive.Close()
+
+		for _, f := range files {
+			if err := archive.WriteFile(f.Name, f.Data); err != nil {
+				return LevelResult{ID: job.id, Err: err}, false
+			}
+		}
+	}
+
+	return LevelResult{ID: job.id, Files: files}, false
+}
@@ -0,0 +1,81 @@
+package mm1warc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	gzip "github.com/klauspost/compress/gzip"
+)
+
+// writeWARCRecord appends one self-contained gzip member holding a single
+// WARC response record for id to w, the way a real .warc.gz does (each
+// record individually gzipped so it can be seeked to by CDX offset).
+func writeWARCRecord(w io.Writer, id string, body []byte) error {
+	gw, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(gw, "WARC/1.0\r\n")
+	fmt.Fprintf(gw, "WARC-Type: response\r\n")
+	fmt.Fprintf(gw, "WARC-Target-URI: http://example.com/levels/%s\r\n", id)
+	fmt.Fprintf(gw, "\r\n")
+	gw.Write(body)
+	fmt.Fprintf(gw, "\r\n\r\n")
+
+	return gw.Close()
+}
+
+// TestExtractWARCManyFailuresDoesNotHang guards against a worker pool that
+// only drains decode errors through a side channel bounded well below the
+// number of records that can fail in one run -- that design deadlocks
+// every worker (and then the record-reading goroutine behind them) the
+// moment the error backlog exceeds the channel's capacity. ExtractWARC
+// must be able to report an arbitrary number of failures without ever
+// blocking.
+func TestExtractWARCManyFailuresDoesNotHang(t *testing.T) {
+	const jobs = 2
+	const numRecords = 10 * jobs
+
+	var buf bytes.Buffer
+	for i := 0; i < numRecords; i++ {
+		id := fmt.Sprintf("level-%04d", i)
+		// "200" with no ASH0 bundles: DecodeLevel gets past the status
+		// check and then fails splitting, so every record surfaces on
+		// the results channel as an error rather than being skipped.
+		if err := writeWARCRecord(&buf, id, []byte("HTTP/1.1 200 OK")); err != nil {
+			t.Fatalf("writeWARCRecord: %v", err)
+		}
+	}
+
+	extractor := NewExtractor(Options{Jobs: jobs})
+	results, err := extractor.ExtractWARC(&buf)
+	if err != nil {
+		t.Fatalf("ExtractWARC: %v", err)
+	}
+
+	seen := 0
+	failed := 0
+	timeout := time.After(10 * time.Second)
+	for seen < numRecords {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				t.Fatalf("results closed early: got %d of %d records", seen, numRecords)
+			}
+			seen++
+			if result.Err != nil {
+				failed++
+			}
+		case <-timeout:
+			t.Fatalf("timed out after %d of %d records: worker pool appears to have deadlocked", seen, numRecords)
+		}
+	}
+
+	if failed != numRecords {
+		t.Fatalf("expected all %d records to fail decoding, got %d failures", numRecords, failed)
+	}
+}
@@ -0,0 +1,55 @@
+// Package mm1warc decodes Nintendo course-world level captures out of
+// WARC archives, as produced by the mm1-unarchive crawl. It exposes both a
+// streaming Extractor for whole WARCs and a lower-level DecodeLevel for
+// callers that already have a single record's bytes in hand.
+package mm1warc
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// OutputFormat selects how a decoded level is packaged on disk.
+type OutputFormat string
+
+const (
+	FormatTarZst OutputFormat = "tar.zst"
+	FormatTarGz  OutputFormat = "tar.gz"
+	FormatTarXz  OutputFormat = "tar.xz"
+	FormatTarBz2 OutputFormat = "tar.bz2"
+	FormatZip    OutputFormat = "zip"
+	FormatDir    OutputFormat = "dir"
+)
+
+// ParseFormat validates a user-supplied format string, such as a CLI
+// --format flag value.
+func ParseFormat(s string) (OutputFormat, error) {
+	switch OutputFormat(s) {
+	case FormatTarZst, FormatTarGz, FormatTarXz, FormatTarBz2, FormatZip, FormatDir:
+		return OutputFormat(s), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want one of tar.zst, tar.gz, tar.xz, tar.bz2, zip, dir)", s)
+	}
+}
+
+// Ext returns the filename suffix a given format produces, without the
+// leading dot.
+func (f OutputFormat) Ext() string {
+	switch f {
+	case FormatDir:
+		return ""
+	default:
+		return string(f)
+	}
+}
+
+// ArchivePath returns the path NewArchiveWriter will create for id under
+// outputDir in the given format, without opening anything. Callers that
+// need to check whether output already exists (e.g. resumable extraction)
+// use this instead of re-deriving the naming scheme themselves.
+func ArchivePath(format OutputFormat, outputDir, id string) string {
+	if format == FormatDir {
+		return filepath.Join(outputDir, id)
+	}
+	return filepath.Join(outputDir, id+"."+format.Ext())
+}
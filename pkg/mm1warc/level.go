@@ -0,0 +1,89 @@
+package mm1warc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	ash0 "github.com/PretendoNetwork/ASH0"
+)
+
+// LevelFile is one named blob decoded from a single level record: a
+// thumbnail or a CDT world.
+type LevelFile struct {
+	Name string
+	Data []byte
+}
+
+var levelFileNames = []string{"thumbnail0.tnl", "course_data.cdt", "course_data_sub.cdt", "thumbnail1.tnl"}
+
+// Level holds the four blobs packed into every extracted level record:
+//
+//	Thumbnail0 (8 byte checksum + JPEG data) - Level preview of main world
+//	CourseData (cdt level data) - Main world data
+//	CourseDataSub (cdt level data) - Sub world data
+//	Thumbnail1 (8 byte checksum + JPEG data) - Level thumbnail
+type Level struct {
+	Thumbnail0    []byte
+	CourseData    []byte
+	CourseDataSub []byte
+	Thumbnail1    []byte
+}
+
+// Files returns Level's blobs as LevelFiles, in the same order and under
+// the same names they have always been packaged with.
+func (l *Level) Files() []LevelFile {
+	return []LevelFile{
+		{Name: levelFileNames[0], Data: l.Thumbnail0},
+		{Name: levelFileNames[1], Data: l.CourseData},
+		{Name: levelFileNames[2], Data: l.CourseDataSub},
+		{Name: levelFileNames[3], Data: l.Thumbnail1},
+	}
+}
+
+// ErrNotFound is returned by DecodeLevel when the record's HTTP status
+// line was not a 200 (e.g. a capture of a missing level), so there is
+// nothing to decode.
+var ErrNotFound = errors.New("mm1warc: record is not a 200 response")
+
+// DecodeLevel decodes the raw body of a single WARC response record for a
+// level -- HTTP response headers followed by four concatenated ASH0
+// bundles -- into its four blobs, without touching disk.
+func DecodeLevel(data []byte) (*Level, error) {
+	bufReader := bufio.NewReader(bytes.NewReader(data))
+	status, _, err := bufReader.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.Contains(string(status), "200") {
+		return nil, ErrNotFound
+	}
+
+	splitData, err := splitAsh0Bundle(data)
+	if err != nil {
+		return nil, fmt.Errorf("splitting ASH0 bundles: %w", err)
+	}
+
+	if len(splitData) != len(levelFileNames) {
+		return nil, fmt.Errorf("expected %d ASH0 bundles, got %d", len(levelFileNames), len(splitData))
+	}
+
+	decompressed := make([][]byte, len(splitData))
+	for i, bundle := range splitData {
+		d := ash0.Decompress(bundle)
+		if d == nil {
+			return nil, errors.New("mm1warc: failed to decompress ASH0 bundle")
+		}
+		decompressed[i] = d
+	}
+
+	return &Level{
+		Thumbnail0:    decompressed[0],
+		CourseData:    decompressed[1],
+		CourseDataSub: decompressed[2],
+		Thumbnail1:    decompressed[3],
+	}, nil
+}